@@ -0,0 +1,103 @@
+// Package container defines the in-memory representation of a container
+// that is shared by every execution platform. Platform specific fields and
+// behaviour live in container_windows.go; this file
+// holds only what every backend needs: identity, config, state, and the
+// handful of filesystem helpers used while starting and stopping it.
+package container
+
+import (
+	"sync"
+
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/runconfig"
+	"github.com/docker/engine-api/types"
+)
+
+// RWLayer is the subset of the graph driver's mount interface a container
+// needs to bring its root filesystem online. It is injected by the daemon
+// so this package never has to import the graph driver directly.
+type RWLayer interface {
+	Mount(mountLabel string) (string, error)
+	Unmount() error
+}
+
+// CommonContainer holds the fields and methods common to all containers,
+// regardless of the execution platform.
+type CommonContainer struct {
+	sync.Mutex
+
+	ID                   string
+	Name                 string
+	Path                 string
+	Args                 []string
+	Config               *runconfig.Config
+	ImageID              string
+	NetworkSettings      *types.NetworkSettings
+	HasBeenStartedBefore bool
+	MountLabel           string
+	ProcessLabel         string
+	BaseFS               string
+
+	State   *State
+	Monitor *Monitor
+	RWLayer RWLayer
+
+	hostConfig *runconfig.HostConfig
+	command    *execdriver.Command
+}
+
+// NewBaseContainer creates a CommonContainer with its state and restart
+// monitor initialized, ready for a platform-specific Container to embed.
+func NewBaseContainer(id string) CommonContainer {
+	return CommonContainer{
+		ID:      id,
+		State:   NewState(),
+		Monitor: NewMonitor(),
+	}
+}
+
+// HostConfig returns the container's host configuration.
+func (container *CommonContainer) HostConfig() *runconfig.HostConfig {
+	return container.hostConfig
+}
+
+// SetHostConfig sets the container's host configuration. It is called by
+// the daemon when a container is created or its host config is updated.
+func (container *CommonContainer) SetHostConfig(hostConfig *runconfig.HostConfig) {
+	container.hostConfig = hostConfig
+}
+
+func (container *CommonContainer) getMountLabel() string {
+	return container.MountLabel
+}
+
+func (container *CommonContainer) getProcessLabel() string {
+	return container.ProcessLabel
+}
+
+func (container *CommonContainer) rootfsPath() string {
+	return container.BaseFS
+}
+
+// Mount brings the container's root filesystem online via its RWLayer and
+// records the resulting path as BaseFS. It is a no-op when no RWLayer has
+// been attached, which is the case for Hyper-V isolated containers.
+func (container *CommonContainer) Mount() error {
+	if container.RWLayer == nil {
+		return nil
+	}
+	dir, err := container.RWLayer.Mount(container.getMountLabel())
+	if err != nil {
+		return err
+	}
+	container.BaseFS = dir
+	return nil
+}
+
+// Unmount takes the container's root filesystem back offline.
+func (container *CommonContainer) Unmount() error {
+	if container.RWLayer == nil {
+		return nil
+	}
+	return container.RWLayer.Unmount()
+}
@@ -0,0 +1,555 @@
+// +build windows
+
+package container
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/docker/docker/daemon/execdriver"
+	derr "github.com/docker/docker/errors"
+	"github.com/docker/docker/runconfig"
+	"github.com/docker/docker/volume"
+	"github.com/docker/engine-api/types/network"
+	"github.com/docker/go-connections/nat"
+	"github.com/docker/libnetwork"
+	"github.com/docker/libnetwork/types"
+)
+
+// DefaultPathEnv is deliberately empty on Windows as the default path will be set by
+// the container. Docker has no context of what the default path should be.
+const DefaultPathEnv = ""
+
+// errSystemNotSupported is returned for queries that only make sense on
+// platforms with a routable network namespace, which Windows does not have.
+var errSystemNotSupported = errors.New("not supported on Windows")
+
+// Backend is the subset of daemon functionality that a Windows container
+// needs in order to start: resolving its image's layer chain, reaching the
+// libnetwork controller, and reading the daemon-wide network defaults. It
+// is satisfied by *daemon.Daemon and wired in through SetBackend when the
+// container is created, so this package never imports daemon.
+type Backend interface {
+	// GetContainerLayers returns the ordered list of read-only layer paths
+	// backing imageID, and the read-write layer folder for containerID.
+	GetContainerLayers(imageID, containerID string) (layerPaths []string, layerFolder string, err error)
+	NetworkController() libnetwork.NetworkController
+	DefaultBridgeName() string
+}
+
+// Container holds fields specific to the Windows implementation. See
+// CommonContainer for standard fields common to all containers.
+type Container struct {
+	CommonContainer
+
+	// Fields below here are platform specific.
+	backend Backend
+}
+
+// SetBackend wires the daemon-provided Backend into the container. It must
+// be called before the container is started.
+func (container *Container) SetBackend(b Backend) {
+	container.backend = b
+}
+
+func killProcessDirectly(container *Container) error {
+	return nil
+}
+
+func (container *Container) setupLinkedContainers() ([]string, error) {
+	return nil, nil
+}
+
+func (container *Container) createDaemonEnvironment(linkedEnv []string) []string {
+	// On Windows, nothing to link. Just return the container environment.
+	return container.Config.Env
+}
+
+func (container *Container) initializeNetworking() error {
+	if container.Config.NetworkDisabled {
+		return nil
+	}
+
+	return container.allocateNetwork()
+}
+
+// buildSandboxOptions translates the container's endpoint configuration into
+// the set of libnetwork.SandboxOption needed to create its network sandbox.
+func (container *Container) buildSandboxOptions() ([]libnetwork.SandboxOption, error) {
+	var sboxOptions []libnetwork.SandboxOption
+	sboxOptions = append(sboxOptions, libnetwork.OptionHostname(container.Config.Hostname))
+
+	for _, extraHost := range container.HostConfig().ExtraHosts {
+		parts := strings.SplitN(extraHost, ":", 2)
+		sboxOptions = append(sboxOptions, libnetwork.OptionExtraHost(parts[0], parts[1]))
+	}
+
+	if container.HostConfig().NetworkMode.IsNone() {
+		return sboxOptions, nil
+	}
+
+	if len(container.HostConfig().DNS) > 0 {
+		sboxOptions = append(sboxOptions, libnetwork.OptionDNS(container.HostConfig().DNS))
+	}
+	if len(container.HostConfig().DNSSearch) > 0 {
+		sboxOptions = append(sboxOptions, libnetwork.OptionDNSSearch(container.HostConfig().DNSSearch))
+	}
+
+	return sboxOptions, nil
+}
+
+// buildCreateEndpointOptions turns the per-network endpoint settings passed
+// through the API into the libnetwork.EndpointOption list required to create
+// an endpoint on network n.
+func (container *Container) buildCreateEndpointOptions(n libnetwork.Network, epConfig *network.EndpointSettings) ([]libnetwork.EndpointOption, error) {
+	var createOptions []libnetwork.EndpointOption
+
+	if epConfig != nil {
+		ipam := epConfig.IPAMConfig
+		if ipam != nil && (ipam.IPv4Address != "" || ipam.IPv6Address != "") {
+			createOptions = append(createOptions,
+				libnetwork.CreateOptionIpam(net.ParseIP(ipam.IPv4Address), net.ParseIP(ipam.IPv6Address), nil, nil))
+		}
+
+		for _, alias := range epConfig.Aliases {
+			createOptions = append(createOptions, libnetwork.CreateOptionMyAlias(alias))
+		}
+	}
+
+	if container.Config.MacAddress != "" {
+		mac, err := net.ParseMAC(container.Config.MacAddress)
+		if err != nil {
+			return nil, err
+		}
+		createOptions = append(createOptions, libnetwork.CreateOptionMacAddress(mac))
+	}
+
+	if !container.HostConfig().NetworkMode.IsPrivate() {
+		createOptions = append(createOptions, libnetwork.CreateOptionPortMapping(getPortBindings(container.HostConfig().PortBindings)))
+	}
+
+	return createOptions, nil
+}
+
+func getPortBindings(bindings nat.PortMap) []types.PortBinding {
+	var portBindings []types.PortBinding
+	for port, bindingList := range bindings {
+		for _, binding := range bindingList {
+			hostPort, err := strconv.Atoi(binding.HostPort)
+			if err != nil {
+				continue
+			}
+			portBindings = append(portBindings, types.PortBinding{
+				Proto:    types.ParseProtocol(port.Proto()),
+				Port:     uint16(port.Int()),
+				HostIP:   net.ParseIP(binding.HostIP),
+				HostPort: uint16(hostPort),
+			})
+		}
+	}
+	return portBindings
+}
+
+// connectToNetwork creates an endpoint in network idOrName and attaches the
+// container's sandbox to it, persisting the resulting EndpointID in the
+// container's NetworkSettings so it can be looked up and cleaned up later.
+func (container *Container) connectToNetwork(idOrName string, updateSettings bool) (err error) {
+	if container.HostConfig().NetworkMode.IsContainer() {
+		return runconfig.ErrConflictSharedNetwork
+	}
+
+	if container.HostConfig().NetworkMode.IsNone() {
+		return nil
+	}
+
+	controller := container.backend.NetworkController()
+	n, err := controller.NetworkByName(idOrName)
+	if err != nil {
+		if _, ok := err.(libnetwork.ErrNoSuchNetwork); !ok {
+			return err
+		}
+		n, err = controller.NetworkByID(idOrName)
+		if err != nil {
+			return err
+		}
+	}
+
+	var epConfig *network.EndpointSettings
+	if container.NetworkSettings != nil && container.NetworkSettings.Networks != nil {
+		epConfig = container.NetworkSettings.Networks[n.Name()]
+	}
+
+	createOptions, err := container.buildCreateEndpointOptions(n, epConfig)
+	if err != nil {
+		return err
+	}
+
+	endpointName := strings.TrimPrefix(container.Name, "/")
+	ep, err := n.CreateEndpoint(endpointName, createOptions...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if e := ep.Delete(false); e != nil {
+				logrus.Warnf("Could not rollback container connection to network %s", idOrName)
+			}
+		}
+	}()
+
+	sb := controller.SandboxByID(container.ID)
+	if sb == nil {
+		sboxOptions, err := container.buildSandboxOptions()
+		if err != nil {
+			return err
+		}
+		sb, err = controller.NewSandbox(container.ID, sboxOptions...)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := ep.Join(sb); err != nil {
+		return err
+	}
+
+	if updateSettings {
+		return container.updateEndpointNetworkSettings(n, ep)
+	}
+
+	return nil
+}
+
+// updateEndpointNetworkSettings persists the endpoint id and resolved
+// addressing for network n into the container's NetworkSettings.
+func (container *Container) updateEndpointNetworkSettings(n libnetwork.Network, ep libnetwork.Endpoint) error {
+	if container.NetworkSettings == nil || container.NetworkSettings.Networks == nil {
+		return nil
+	}
+	settings, ok := container.NetworkSettings.Networks[n.Name()]
+	if !ok {
+		settings = &network.EndpointSettings{}
+		container.NetworkSettings.Networks[n.Name()] = settings
+	}
+	settings.NetworkID = n.ID()
+	settings.EndpointID = ep.ID()
+
+	epInfo := ep.Info()
+	if epInfo == nil {
+		return nil
+	}
+	if iface := epInfo.Iface(); iface != nil && iface.Address() != nil {
+		settings.IPAddress = iface.Address().IP.String()
+	}
+	if gw := epInfo.Gateway(); gw != nil {
+		settings.Gateway = gw.String()
+	}
+	return nil
+}
+
+// networkSettingsForMode returns the resolved endpoint settings for the
+// network backing the given NetworkMode prefix ("default"/"" or a network
+// name/id), or nil if the container has not been connected to it yet.
+func (container *Container) networkSettingsForMode(mode string) *network.EndpointSettings {
+	if container.NetworkSettings == nil {
+		return nil
+	}
+	name := mode
+	if name == "" || name == "default" {
+		name = container.HostConfig().NetworkMode.NetworkName()
+	}
+	return container.NetworkSettings.Networks[name]
+}
+
+// ConnectToNetwork connects a container to the network
+func (container *Container) ConnectToNetwork(idOrName string) error {
+	return container.connectToNetwork(idOrName, true)
+}
+
+// DisconnectFromNetwork disconnects a container from, the network
+func (container *Container) DisconnectFromNetwork(n libnetwork.Network) error {
+	var epName string
+	if container.NetworkSettings != nil {
+		for name, settings := range container.NetworkSettings.Networks {
+			if settings.NetworkID == n.ID() {
+				epName = name
+				delete(container.NetworkSettings.Networks, name)
+				break
+			}
+		}
+	}
+
+	ep, err := n.EndpointByName(strings.TrimPrefix(container.Name, "/"))
+	if err != nil {
+		if _, ok := err.(libnetwork.ErrNoSuchEndpoint); ok {
+			return nil
+		}
+		return err
+	}
+
+	if err := ep.Leave(container.backend.NetworkController().SandboxByID(container.ID)); err != nil {
+		return fmt.Errorf("container %s failed to leave network %s: %v", container.ID, epName, err)
+	}
+
+	return ep.Delete(false)
+}
+
+// ForceEndpointDelete deletes an endpoint from a network forcibly, ignoring
+// any sandbox attachment errors. It is used to clean up endpoints that were
+// left behind by a container that no longer exists, for example after a
+// daemon restart finds a stale endpoint referencing a crashed container.
+func ForceEndpointDelete(name string, n libnetwork.Network) error {
+	ep, err := n.EndpointByName(name)
+	if err != nil {
+		if _, ok := err.(libnetwork.ErrNoSuchEndpoint); ok {
+			return nil
+		}
+		return err
+	}
+	return ep.Delete(true)
+}
+
+func (container *Container) setupWorkingDirectory() error {
+	return nil
+}
+
+func populateCommand(c *Container, env []string) error {
+	en := &execdriver.Network{
+		Interface: nil,
+	}
+
+	parts := strings.SplitN(string(c.HostConfig().NetworkMode), ":", 2)
+	switch parts[0] {
+	case "none":
+	case "default", "": // empty string to support existing containers
+		if !c.Config.NetworkDisabled {
+			en.Interface = &execdriver.NetworkInterface{
+				MacAddress:   c.Config.MacAddress,
+				Bridge:       c.backend.DefaultBridgeName(),
+				PortBindings: c.HostConfig().PortBindings,
+			}
+
+			if settings := c.networkSettingsForMode(parts[0]); settings != nil {
+				en.Interface.IPAddress = settings.IPAddress
+				en.Interface.Gateway = settings.Gateway
+				if n, err := c.backend.NetworkController().NetworkByID(settings.NetworkID); err == nil {
+					en.Interface.Bridge = n.Name()
+				}
+			}
+		}
+	default:
+		return derr.ErrorCodeInvalidNetworkMode.WithArgs(c.HostConfig().NetworkMode)
+	}
+
+	resources, err := buildResources(c.HostConfig())
+	if err != nil {
+		return err
+	}
+
+	credentialSpec, err := resolveCredentialSpec(c.HostConfig().SecurityOpt)
+	if err != nil {
+		return err
+	}
+
+	// fsBinds excludes the named-pipe entries so the execdriver only attempts
+	// a regular directory bind mount for the rest of HostConfig().Binds. It
+	// is forwarded via execdriver.Command.Binds rather than written back to
+	// HostConfig().Binds, since the latter is the container's persisted
+	// configuration and mutating it here would permanently drop the named
+	// pipe entries from future starts.
+	isolated := c.HostConfig().Isolation.IsHyperV()
+	fsBinds, mappedPipes, err := splitNamedPipeMounts(c.HostConfig().Binds, isolated)
+	if err != nil {
+		return err
+	}
+
+	// TODO Windows. Further refactoring required (privileged/user)
+	processConfig := execdriver.ProcessConfig{
+		Privileged:     c.HostConfig().Privileged,
+		Entrypoint:     c.Path,
+		Arguments:      c.Args,
+		Tty:            c.Config.Tty,
+		User:           c.Config.User,
+		ConsoleSize:    c.HostConfig().ConsoleSize,
+		CredentialSpec: credentialSpec,
+	}
+
+	processConfig.Env = env
+
+	layerPaths, layerFolder, err := c.backend.GetContainerLayers(c.ImageID, c.ID)
+	if err != nil {
+		return derr.ErrorCodeGetGraph.WithArgs(c.ImageID, err)
+	}
+
+	c.command = &execdriver.Command{
+		CommonCommand: execdriver.CommonCommand{
+			ID:            c.ID,
+			Rootfs:        c.rootfsPath(),
+			InitPath:      "/.dockerinit",
+			WorkingDir:    c.Config.WorkingDir,
+			Network:       en,
+			MountLabel:    c.getMountLabel(),
+			Resources:     resources,
+			ProcessConfig: processConfig,
+			ProcessLabel:  c.getProcessLabel(),
+		},
+		FirstStart:  !c.HasBeenStartedBefore,
+		LayerFolder: layerFolder,
+		LayerPaths:  layerPaths,
+		Hostname:    c.Config.Hostname,
+		Isolated:    isolated,
+		Binds:       fsBinds,
+		MappedPipes: mappedPipes,
+	}
+
+	return nil
+}
+
+// GetSize returns real size & virtual size
+func (container *Container) getSize() (int64, int64) {
+	// TODO Windows
+	return 0, 0
+}
+
+// setNetworkNamespaceKey is a no-op on Windows.
+func (container *Container) setNetworkNamespaceKey(pid int) error {
+	return nil
+}
+
+// allocateNetwork connects the container to every network requested through
+// its NetworkMode/NetworkSettings, creating the endpoints (and, for the
+// default network, the sandbox) needed before the container can be started.
+func (container *Container) allocateNetwork() error {
+	mode := container.HostConfig().NetworkMode
+	if container.Config.NetworkDisabled || mode.IsContainer() {
+		return nil
+	}
+
+	networks := []string{mode.NetworkName()}
+	if container.NetworkSettings != nil {
+		for name := range container.NetworkSettings.Networks {
+			if name != networks[0] {
+				networks = append(networks, name)
+			}
+		}
+	}
+
+	for _, name := range networks {
+		if err := container.connectToNetwork(name, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateNetwork re-applies the container's endpoint configuration, for
+// example after `docker network connect`/`disconnect` changed the set of
+// networks the container should be attached to while it is running.
+func (container *Container) updateNetwork() error {
+	if container.NetworkSettings == nil {
+		return nil
+	}
+
+	controller := container.backend.NetworkController()
+	for name, settings := range container.NetworkSettings.Networks {
+		n, err := controller.NetworkByID(settings.NetworkID)
+		if err != nil {
+			return err
+		}
+		ep, err := n.EndpointByID(settings.EndpointID)
+		if err != nil {
+			return err
+		}
+		if err := ep.Leave(controller.SandboxByID(container.ID)); err != nil {
+			return err
+		}
+		if err := ep.Delete(false); err != nil {
+			return err
+		}
+		if err := container.connectToNetwork(name, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// releaseNetwork disconnects the container from every network it is attached
+// to, tearing down its sandbox in the process.
+func (container *Container) releaseNetwork() {
+	if container.NetworkSettings == nil {
+		return
+	}
+
+	controller := container.backend.NetworkController()
+	for _, settings := range container.NetworkSettings.Networks {
+		n, err := controller.NetworkByID(settings.NetworkID)
+		if err != nil {
+			logrus.Warnf("error locating network %s for container %s release: %v", settings.NetworkID, container.ID, err)
+			continue
+		}
+		if err := container.DisconnectFromNetwork(n); err != nil {
+			logrus.Warnf("error disconnecting container %s from network %s: %v", container.ID, n.Name(), err)
+		}
+	}
+
+	if sb := controller.SandboxByID(container.ID); sb != nil {
+		if err := sb.Delete(); err != nil {
+			logrus.Warnf("error deleting sandbox for container %s: %v", container.ID, err)
+		}
+	}
+}
+
+// appendNetworkMounts appends any network mounts to the array of mount points passed in.
+// Windows does not support network mounts (not to be confused with SMB network mounts), so
+// this is a no-op.
+func appendNetworkMounts(container *Container, volumeMounts []volume.MountPoint) ([]volume.MountPoint, error) {
+	return volumeMounts, nil
+}
+
+func (container *Container) setupIpcDirs() error {
+	return nil
+}
+
+func (container *Container) unmountIpcMounts(unmount func(pth string) error) {
+}
+
+func detachMounted(path string) error {
+	return nil
+}
+
+func (container *Container) ipcMounts() []execdriver.Mount {
+	return nil
+}
+
+func getDefaultRouteMtu() (int, error) {
+	return -1, errSystemNotSupported
+}
+
+// conditionalMountOnStart is a platform specific helper function during the
+// container start to call mount.
+func (container *Container) conditionalMountOnStart() error {
+	// We do not mount if a Hyper-V container
+	if !container.HostConfig().Isolation.IsHyperV() {
+		if err := container.Mount(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// conditionalUnmountOnCleanup is a platform specific helper function called
+// during the cleanup of a container to unmount.
+func (container *Container) conditionalUnmountOnCleanup() {
+	// We do not unmount if a Hyper-V container
+	if !container.HostConfig().Isolation.IsHyperV() {
+		if err := container.Unmount(); err != nil {
+			logrus.Errorf("%v: Failed to umount filesystem: %v", container.ID, err)
+		}
+	}
+}
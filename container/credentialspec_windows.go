@@ -0,0 +1,79 @@
+// +build windows
+
+package container
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	credentialSpecOptPrefix      = "credentialspec="
+	credentialSpecFileScheme     = "file://"
+	credentialSpecRegistryScheme = "registry://"
+
+	// credentialSpecDir is where `docker run --security-opt credentialspec=file://...`
+	// looks up credential spec files by name.
+	credentialSpecDir = `C:\ProgramData\docker\credentialspecs`
+
+	// credentialSpecRegistryKey is where `registry://...` credential specs
+	// are read from, one named value per entry.
+	credentialSpecRegistryKey = `SOFTWARE\Microsoft\Windows NT\CurrentVersion\Virtualization\Containers\CredentialSpecs`
+)
+
+// resolveCredentialSpec looks for a `credentialspec=` security option among
+// securityOpts and, if found, resolves it to the raw JSON blob HCS needs to
+// join the container to an Active Directory gMSA. It returns an empty
+// string if no credential spec was requested.
+func resolveCredentialSpec(securityOpts []string) (string, error) {
+	for _, opt := range securityOpts {
+		if !strings.HasPrefix(opt, credentialSpecOptPrefix) {
+			continue
+		}
+
+		ref := strings.TrimPrefix(opt, credentialSpecOptPrefix)
+		switch {
+		case strings.HasPrefix(ref, credentialSpecFileScheme):
+			return readCredentialSpecFile(strings.TrimPrefix(ref, credentialSpecFileScheme))
+		case strings.HasPrefix(ref, credentialSpecRegistryScheme):
+			return readCredentialSpecRegistry(strings.TrimPrefix(ref, credentialSpecRegistryScheme))
+		default:
+			return "", fmt.Errorf("invalid credential spec security option %q: must be file:// or registry://", opt)
+		}
+	}
+
+	return "", nil
+}
+
+func readCredentialSpecFile(name string) (string, error) {
+	if filepath.IsAbs(name) || strings.Contains(name, "..") {
+		return "", fmt.Errorf("invalid credential spec file name %q", name)
+	}
+
+	path := filepath.Join(credentialSpecDir, name)
+	blob, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to read credential spec %q: %v", path, err)
+	}
+
+	return string(blob), nil
+}
+
+func readCredentialSpecRegistry(name string) (string, error) {
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, credentialSpecRegistryKey, registry.QUERY_VALUE)
+	if err != nil {
+		return "", fmt.Errorf("unable to open credential spec registry key: %v", err)
+	}
+	defer k.Close()
+
+	blob, _, err := k.GetStringValue(name)
+	if err != nil {
+		return "", fmt.Errorf("unable to read credential spec %q from registry: %v", name, err)
+	}
+
+	return blob, nil
+}
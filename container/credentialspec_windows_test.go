@@ -0,0 +1,35 @@
+// +build windows
+
+package container
+
+import "testing"
+
+func TestResolveCredentialSpecNoneRequested(t *testing.T) {
+	spec, err := resolveCredentialSpec([]string{"no-new-privileges"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spec != "" {
+		t.Errorf("expected an empty credential spec, got %q", spec)
+	}
+}
+
+func TestResolveCredentialSpecInvalidScheme(t *testing.T) {
+	_, err := resolveCredentialSpec([]string{"credentialspec=http://example.com/spec.json"})
+	if err == nil {
+		t.Fatal("expected an error for an unsupported credential spec scheme")
+	}
+}
+
+func TestReadCredentialSpecFileRejectsPathTraversal(t *testing.T) {
+	cases := []string{
+		`..\escape.json`,
+		`C:\absolute\path.json`,
+	}
+
+	for _, name := range cases {
+		if _, err := readCredentialSpecFile(name); err == nil {
+			t.Errorf("expected an error for credential spec file name %q", name)
+		}
+	}
+}
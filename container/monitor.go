@@ -0,0 +1,44 @@
+package container
+
+import "sync"
+
+// Monitor tracks the restart-policy bookkeeping for a container: how many
+// times it has been restarted by its supervising goroutine, and whether
+// restarts have been disabled (for example because the container was
+// explicitly stopped or removed).
+type Monitor struct {
+	mu           sync.Mutex
+	restartCount int
+	stopped      bool
+}
+
+// NewMonitor creates a Monitor with no restarts recorded yet.
+func NewMonitor() *Monitor {
+	return &Monitor{}
+}
+
+// RestartCount returns how many times the container has been restarted.
+func (m *Monitor) RestartCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.restartCount
+}
+
+// ShouldRestart records a restart attempt and reports whether the
+// supervising goroutine should go ahead with it.
+func (m *Monitor) ShouldRestart() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopped {
+		return false
+	}
+	m.restartCount++
+	return true
+}
+
+// Stop prevents any further restarts from being attempted.
+func (m *Monitor) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stopped = true
+}
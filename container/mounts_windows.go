@@ -0,0 +1,53 @@
+// +build windows
+
+package container
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/daemon/execdriver"
+)
+
+// namedPipePrefix is the prefix every Windows named pipe path starts with.
+const namedPipePrefix = `\\.\pipe\`
+
+// errNamedPipesUnsupportedOnHyperV is returned when a named-pipe mount is
+// requested for a Hyper-V isolated container, which older builds cannot
+// expose host pipes into.
+var errNamedPipesUnsupportedOnHyperV = errors.New("named pipe mounts are not supported on Hyper-V isolated containers")
+
+func isNamedPipePath(p string) bool {
+	return strings.HasPrefix(p, namedPipePrefix)
+}
+
+// splitNamedPipeMounts pulls `\\.\pipe\host:\\.\pipe\container` entries out
+// of binds, returning the remaining filesystem binds separately from the
+// named pipes to be mapped into the container.
+func splitNamedPipeMounts(binds []string, isolated bool) ([]string, []execdriver.MappedPipe, error) {
+	var (
+		fsBinds []string
+		pipes   []execdriver.MappedPipe
+	)
+
+	for _, bind := range binds {
+		parts := strings.SplitN(bind, ":", 3)
+		if len(parts) >= 2 && isNamedPipePath(parts[0]) && isNamedPipePath(parts[1]) {
+			if len(parts) == 3 {
+				return nil, nil, fmt.Errorf("named pipe mount %q does not support a read/write mode option", bind)
+			}
+			if isolated {
+				return nil, nil, errNamedPipesUnsupportedOnHyperV
+			}
+			pipes = append(pipes, execdriver.MappedPipe{
+				HostPath:      parts[0],
+				ContainerPath: parts[1],
+			})
+			continue
+		}
+		fsBinds = append(fsBinds, bind)
+	}
+
+	return fsBinds, pipes, nil
+}
@@ -0,0 +1,39 @@
+// +build windows
+
+package container
+
+import "testing"
+
+func TestSplitNamedPipeMounts(t *testing.T) {
+	binds := []string{
+		`c:\host:c:\container`,
+		`\\.\pipe\hostpipe:\\.\pipe\containerpipe`,
+	}
+
+	fsBinds, pipes, err := splitNamedPipeMounts(binds, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fsBinds) != 1 || fsBinds[0] != `c:\host:c:\container` {
+		t.Errorf("expected the filesystem bind to survive unfiltered, got %v", fsBinds)
+	}
+	if len(pipes) != 1 || pipes[0].HostPath != `\\.\pipe\hostpipe` || pipes[0].ContainerPath != `\\.\pipe\containerpipe` {
+		t.Errorf("expected one mapped pipe, got %v", pipes)
+	}
+}
+
+func TestSplitNamedPipeMountsRejectsHyperVIsolation(t *testing.T) {
+	binds := []string{`\\.\pipe\hostpipe:\\.\pipe\containerpipe`}
+
+	if _, _, err := splitNamedPipeMounts(binds, true); err != errNamedPipesUnsupportedOnHyperV {
+		t.Fatalf("expected errNamedPipesUnsupportedOnHyperV, got %v", err)
+	}
+}
+
+func TestSplitNamedPipeMountsRejectsModeOption(t *testing.T) {
+	binds := []string{`\\.\pipe\hostpipe:\\.\pipe\containerpipe:ro`}
+
+	if _, _, err := splitNamedPipeMounts(binds, false); err == nil {
+		t.Fatal("expected an error for a named pipe mount with a mode option")
+	}
+}
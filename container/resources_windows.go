@@ -0,0 +1,137 @@
+// +build windows
+
+package container
+
+import (
+	"strconv"
+
+	"github.com/docker/docker/daemon/execdriver"
+	derr "github.com/docker/docker/errors"
+	"github.com/docker/docker/runconfig"
+)
+
+const (
+	// windowsMinCPUShares is the smallest CPUShares value HCS will accept.
+	windowsMinCPUShares = 1
+	// windowsMaxCPUShares is the largest CPUShares value HCS will accept.
+	windowsMaxCPUShares = 10000
+)
+
+// buildResources validates a container's resource limits and translates them
+// into the execdriver.Resources HCS understands, clamping a non-zero CPUShares
+// to the 1-10000 range the platform accepts. A zero (unset) CPUShares is
+// passed through unchanged.
+func buildResources(hostConfig *runconfig.HostConfig) (*execdriver.Resources, error) {
+	cpuLimitsSet := 0
+	if hostConfig.CPUCount != 0 {
+		cpuLimitsSet++
+	}
+	if hostConfig.CPUPercent != 0 {
+		cpuLimitsSet++
+	}
+	if hostConfig.CPUShares != 0 {
+		cpuLimitsSet++
+	}
+	if cpuLimitsSet > 1 {
+		return nil, derr.ErrorCodeConflictingCPULimits.WithArgs()
+	}
+
+	if hostConfig.CPUPercent < 0 || hostConfig.CPUPercent > 100 {
+		return nil, derr.ErrorCodeInvalidCPUPercent.WithArgs(hostConfig.CPUPercent)
+	}
+
+	cpuShares := hostConfig.CPUShares
+	switch {
+	case cpuShares == 0:
+	case cpuShares < windowsMinCPUShares:
+		cpuShares = windowsMinCPUShares
+	case cpuShares > windowsMaxCPUShares:
+		cpuShares = windowsMaxCPUShares
+	}
+
+	var sandboxSize uint64
+	if opt, ok := hostConfig.StorageOpt["size"]; ok {
+		size, err := strconv.ParseUint(opt, 10, 64)
+		if err != nil {
+			return nil, derr.ErrorCodeInvalidStorageOptSize.WithArgs(opt)
+		}
+		sandboxSize = size
+	}
+
+	return &execdriver.Resources{
+		CommonResources: execdriver.CommonResources{
+			CPUShares: cpuShares,
+		},
+		CPUCount:           hostConfig.CPUCount,
+		CPUPercent:         hostConfig.CPUPercent,
+		Memory:             hostConfig.Memory,
+		IOMaximumIOps:      hostConfig.IOMaximumIOps,
+		IOMaximumBandwidth: hostConfig.IOMaximumBandwidth,
+		SandboxSize:        sandboxSize,
+	}, nil
+}
+
+// UpdateResources validates and merges a new set of resource limits coming
+// from `docker update` into the container's host config, then rebuilds the
+// execdriver.Resources so the running container picks up the change.
+func (container *Container) UpdateResources(update *execdriver.Resources) error {
+	container.Lock()
+	defer container.Unlock()
+
+	cpuFieldsSet := 0
+	if update.CPUShares != 0 {
+		cpuFieldsSet++
+	}
+	if update.CPUCount != 0 {
+		cpuFieldsSet++
+	}
+	if update.CPUPercent != 0 {
+		cpuFieldsSet++
+	}
+	if cpuFieldsSet > 1 {
+		return derr.ErrorCodeConflictingCPULimits.WithArgs()
+	}
+
+	// Validate against a copy of the host config first: buildResources can
+	// reject the merged result, and the live HostConfig() must not be
+	// mutated until we know the update will succeed.
+	merged := *container.HostConfig()
+
+	if update.CPUShares != 0 {
+		merged.CPUShares = update.CPUShares
+		merged.CPUCount = 0
+		merged.CPUPercent = 0
+	}
+	if update.CPUCount != 0 {
+		merged.CPUCount = update.CPUCount
+		merged.CPUShares = 0
+		merged.CPUPercent = 0
+	}
+	if update.CPUPercent != 0 {
+		merged.CPUPercent = update.CPUPercent
+		merged.CPUShares = 0
+		merged.CPUCount = 0
+	}
+	if update.Memory != 0 {
+		merged.Memory = update.Memory
+	}
+	if update.IOMaximumIOps != 0 {
+		merged.IOMaximumIOps = update.IOMaximumIOps
+	}
+	if update.IOMaximumBandwidth != 0 {
+		merged.IOMaximumBandwidth = update.IOMaximumBandwidth
+	}
+
+	resources, err := buildResources(&merged)
+	if err != nil {
+		return err
+	}
+
+	*container.HostConfig() = merged
+
+	if container.command != nil {
+		container.command.Resources = resources
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+// +build windows
+
+package container
+
+import (
+	"testing"
+
+	"github.com/docker/docker/daemon/execdriver"
+	"github.com/docker/docker/runconfig"
+)
+
+func TestBuildResourcesConflictingCPULimits(t *testing.T) {
+	hostConfig := &runconfig.HostConfig{
+		CPUCount:  1,
+		CPUShares: 100,
+	}
+
+	if _, err := buildResources(hostConfig); err == nil {
+		t.Fatal("expected an error when both CPUCount and CPUShares are set")
+	}
+}
+
+func TestBuildResourcesInvalidCPUPercent(t *testing.T) {
+	for _, percent := range []int64{-1, 101} {
+		hostConfig := &runconfig.HostConfig{CPUPercent: percent}
+		if _, err := buildResources(hostConfig); err == nil {
+			t.Fatalf("expected an error for CPUPercent %d", percent)
+		}
+	}
+}
+
+func TestBuildResourcesClampsCPUShares(t *testing.T) {
+	cases := []struct {
+		shares   int64
+		expected int64
+	}{
+		{shares: 0, expected: 0},
+		{shares: -1, expected: windowsMinCPUShares},
+		{shares: windowsMaxCPUShares + 1, expected: windowsMaxCPUShares},
+		{shares: 500, expected: 500},
+	}
+
+	for _, c := range cases {
+		hostConfig := &runconfig.HostConfig{CPUShares: c.shares}
+		resources, err := buildResources(hostConfig)
+		if err != nil {
+			t.Fatalf("unexpected error for CPUShares %d: %v", c.shares, err)
+		}
+		if resources.CPUShares != c.expected {
+			t.Errorf("CPUShares %d: expected clamp to %d, got %d", c.shares, c.expected, resources.CPUShares)
+		}
+	}
+}
+
+func TestBuildResourcesInvalidStorageOptSize(t *testing.T) {
+	hostConfig := &runconfig.HostConfig{
+		StorageOpt: map[string]string{"size": "not-a-number"},
+	}
+
+	if _, err := buildResources(hostConfig); err == nil {
+		t.Fatal("expected an error for a non-numeric storage-opt size")
+	}
+}
+
+func TestUpdateResourcesRejectsConflictingUpdateDoesNotMutateHostConfig(t *testing.T) {
+	container := &Container{}
+	container.SetHostConfig(&runconfig.HostConfig{CPUShares: 100})
+
+	update := &execdriver.Resources{
+		CommonResources: execdriver.CommonResources{CPUShares: 100},
+		CPUCount:        1,
+	}
+	if err := container.UpdateResources(update); err == nil {
+		t.Fatal("expected an error when both CPUCount and CPUShares are set")
+	}
+	if container.HostConfig().CPUShares != 100 {
+		t.Errorf("rejected update must not mutate the live HostConfig, got CPUShares=%d", container.HostConfig().CPUShares)
+	}
+}
+
+func TestUpdateResourcesSwitchesCPUStrategy(t *testing.T) {
+	container := &Container{}
+	container.SetHostConfig(&runconfig.HostConfig{CPUShares: 100})
+
+	update := &execdriver.Resources{CPUCount: 4}
+	if err := container.UpdateResources(update); err != nil {
+		t.Fatalf("unexpected error switching from CPUShares to CPUCount: %v", err)
+	}
+	if container.HostConfig().CPUShares != 0 {
+		t.Errorf("expected CPUShares to be cleared after switching to CPUCount, got %d", container.HostConfig().CPUShares)
+	}
+	if container.HostConfig().CPUCount != 4 {
+		t.Errorf("expected CPUCount to be set to 4, got %d", container.HostConfig().CPUCount)
+	}
+}
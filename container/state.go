@@ -0,0 +1,46 @@
+package container
+
+import "sync"
+
+// State holds the runtime status of a container: whether it is running,
+// its process id, and the result of its most recent exit. It is safe for
+// concurrent use.
+type State struct {
+	sync.Mutex
+
+	Running  bool
+	Paused   bool
+	Pid      int
+	ExitCode int
+	Error    string
+}
+
+// NewState creates a State in the default, not-running condition.
+func NewState() *State {
+	return &State{}
+}
+
+// IsRunning reports whether the container's process is currently running.
+func (s *State) IsRunning() bool {
+	s.Lock()
+	defer s.Unlock()
+	return s.Running
+}
+
+// SetRunning marks the container as running under the given process id.
+func (s *State) SetRunning(pid int) {
+	s.Lock()
+	defer s.Unlock()
+	s.Error = ""
+	s.Running = true
+	s.Pid = pid
+}
+
+// SetStopped marks the container as stopped, recording its exit code.
+func (s *State) SetStopped(exitCode int) {
+	s.Lock()
+	defer s.Unlock()
+	s.Running = false
+	s.Pid = 0
+	s.ExitCode = exitCode
+}